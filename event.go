@@ -0,0 +1,124 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+var eventPool = sync.Pool{New: func() any { return new(Event) }}
+
+// Event is a fluent builder for a single log entry: call a severity
+// constructor such as Info2, attach fields, then flush with Msg or Msgf.
+// An Event is pooled; it must not be used after Msg or Msgf returns.
+type Event struct {
+	logger   *Logger
+	severity Severity
+	ctx      context.Context
+	attrs    []slog.Attr
+	noop     bool
+}
+
+func newEvent(l *Logger, s Severity) *Event {
+	e := eventPool.Get().(*Event)
+	e.logger = l
+	e.severity = s
+	e.ctx = nil
+	e.attrs = e.attrs[:0]
+	e.noop = !l.Enabled(s)
+	return e
+}
+
+// Debug2 returns an Event that logs at SeverityDebug when flushed.
+func Debug2() *Event { return newEvent(std.Load().(*Logger), SeverityDebug) }
+
+// Info2 returns an Event that logs at SeverityInfo when flushed.
+func Info2() *Event { return newEvent(std.Load().(*Logger), SeverityInfo) }
+
+// Warn2 returns an Event that logs at SeverityWarning when flushed.
+func Warn2() *Event { return newEvent(std.Load().(*Logger), SeverityWarning) }
+
+// Error2 returns an Event that logs at SeverityError when flushed.
+func Error2() *Event { return newEvent(std.Load().(*Logger), SeverityError) }
+
+// Debug2 returns an Event that logs at SeverityDebug on l when flushed.
+func (l *Logger) Debug2() *Event { return newEvent(l, SeverityDebug) }
+
+// Info2 returns an Event that logs at SeverityInfo on l when flushed.
+func (l *Logger) Info2() *Event { return newEvent(l, SeverityInfo) }
+
+// Warn2 returns an Event that logs at SeverityWarning on l when flushed.
+func (l *Logger) Warn2() *Event { return newEvent(l, SeverityWarning) }
+
+// Error2 returns an Event that logs at SeverityError on l when flushed.
+func (l *Logger) Error2() *Event { return newEvent(l, SeverityError) }
+
+// Str attaches a string field. It is a no-op if e's severity is disabled.
+func (e *Event) Str(key, val string) *Event {
+	if e.noop {
+		return e
+	}
+	e.attrs = append(e.attrs, slog.String(key, val))
+	return e
+}
+
+// Int attaches an int field. It is a no-op if e's severity is disabled.
+func (e *Event) Int(key string, val int) *Event {
+	if e.noop {
+		return e
+	}
+	e.attrs = append(e.attrs, slog.Int(key, val))
+	return e
+}
+
+// Err attaches err under the "error" key. It is a no-op if err is nil or
+// e's severity is disabled.
+func (e *Event) Err(err error) *Event {
+	if e.noop || err == nil {
+		return e
+	}
+	e.attrs = append(e.attrs, slog.String("error", err.Error()))
+	return e
+}
+
+// Ctx attaches ctx, so that the flushed entry includes the trace, spanId,
+// httpRequest, labels, operation and insertId fields ctx carries, the same
+// way the *WithCtx functions do. It is a no-op if e's severity is disabled.
+func (e *Event) Ctx(ctx context.Context) *Event {
+	if e.noop {
+		return e
+	}
+	e.ctx = ctx
+	return e
+}
+
+// Msg flushes e as a log entry with the message msg and returns e to its
+// pool. e must not be used after Msg returns.
+func (e *Event) Msg(msg string) error {
+	defer eventPool.Put(e)
+	if e.noop {
+		return nil
+	}
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return e.logger.logWithSpan(ctx, e.severity, trace.SpanFromContext(ctx), msg, e.attrs...)
+}
+
+// Msgf flushes e as a log entry with a fmt.Sprintf-formatted message and
+// returns e to its pool. e must not be used after Msgf returns.
+func (e *Event) Msgf(format string, a ...interface{}) error {
+	defer eventPool.Put(e)
+	if e.noop {
+		return nil
+	}
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return e.logger.logWithSpan(ctx, e.severity, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...), e.attrs...)
+}
@@ -0,0 +1,70 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventMsg(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithClock(func() time.Time { return time.Time{} }))
+
+	err := l.Info2().Str("user_id", "u1").Int("retry", 2).Err(errors.New("boom")).Msg("login failed")
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`"severity":"INFO"`,
+		`"message":"login failed"`,
+		`"user_id":"u1"`,
+		`"retry":2`,
+		`"error":"boom"`,
+	} {
+		if !bytes.Contains([]byte(got), []byte(want)) {
+			t.Errorf("got=%v, want substring=%v", got, want)
+		}
+	}
+}
+
+func TestEventNoopWhenDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithSeverity(SeverityWarning))
+
+	if err := l.Info2().Str("user_id", "u1").Msg("dropped"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("buf=%q, want empty", buf.String())
+	}
+}
+
+func TestEventCtx(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithClock(func() time.Time { return time.Time{} }))
+
+	ctx := WithLabels(context.Background(), map[string]string{"env": "test"})
+	if err := l.Info2().Ctx(ctx).Msg("hoge"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"logging.googleapis.com/labels":{"env":"test"}`)) {
+		t.Errorf("buf=%q, want labels field", buf.String())
+	}
+}
+
+func TestEventMsgf(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithClock(func() time.Time { return time.Time{} }))
+
+	if err := l.Error2().Msgf("failed: %s", "boom"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"message":"failed: boom"`)) {
+		t.Errorf("buf=%q, want formatted message", buf.String())
+	}
+}
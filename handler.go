@@ -0,0 +1,249 @@
+package slog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Severity levels for use with a CloudLoggingHandler. LevelDebug, LevelInfo,
+// LevelWarning and LevelError line up with the equivalent log/slog levels;
+// LevelNotice, LevelCritical, LevelAlert and LevelEmergency fill the gaps so
+// that every Cloud Logging severity
+// (https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogSeverity)
+// is reachable through the standard Logger API.
+const (
+	LevelDebug     = slog.LevelDebug
+	LevelInfo      = slog.LevelInfo
+	LevelNotice    = slog.Level(2)
+	LevelWarning   = slog.LevelWarn
+	LevelError     = slog.LevelError
+	LevelCritical  = slog.Level(10)
+	LevelAlert     = slog.Level(12)
+	LevelEmergency = slog.Level(14)
+)
+
+// severityForLevel maps a slog.Level onto the Severity whose range it falls into.
+func severityForLevel(l slog.Level) Severity {
+	switch {
+	case l < LevelDebug:
+		return SeverityDefault
+	case l < LevelInfo:
+		return SeverityDebug
+	case l < LevelNotice:
+		return SeverityInfo
+	case l < LevelWarning:
+		return SeverityNotice
+	case l < LevelError:
+		return SeverityWarning
+	case l < LevelCritical:
+		return SeverityError
+	case l < LevelAlert:
+		return SeverityCritical
+	case l < LevelEmergency:
+		return SeverityAlert
+	default:
+		return SeverityEmergency
+	}
+}
+
+// entryTime formats t as the RFC 3339 string Entry.Time expects, or returns
+// "" if t is zero so the field is omitted and Cloud Logging falls back to
+// ingestion time.
+func entryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func sourceLocation(pc uintptr) *SourceLocation {
+	if pc == 0 {
+		return nil
+	}
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if f.File == "" {
+		return nil
+	}
+	return &SourceLocation{File: f.File, Line: int64(f.Line), Function: f.Function}
+}
+
+// HandlerOptions are options for a CloudLoggingHandler.
+type HandlerOptions struct {
+	// Level reports the minimum record level that will be logged.
+	// If Level is nil, the handler assumes LevelInfo.
+	Level slog.Leveler
+}
+
+// CloudLoggingHandler is a slog.Handler that translates slog.Record values
+// into Entry values in the structured JSON format understood by the Cloud
+// Logging agent and passes them to a Handler for writing.
+// See https://cloud.google.com/logging/docs/structured-logging.
+type CloudLoggingHandler struct {
+	out   *atomic.Value // Handler
+	level slog.Leveler
+	attrs []slog.Attr
+
+	entryPool *sync.Pool
+}
+
+// NewCloudLoggingHandler returns a CloudLoggingHandler that writes to w.
+func NewCloudLoggingHandler(w io.Writer, opts *HandlerOptions) *CloudLoggingHandler {
+	return NewCloudLoggingHandlerWithHandler(NewWriterHandler(w), opts)
+}
+
+// NewCloudLoggingHandlerWithHandler returns a CloudLoggingHandler that
+// passes each translated Entry to out, which may buffer, batch or fan it
+// out instead of writing it inline.
+func NewCloudLoggingHandlerWithHandler(out Handler, opts *HandlerOptions) *CloudLoggingHandler {
+	if opts == nil {
+		opts = &HandlerOptions{}
+	}
+	lvl := opts.Level
+	if lvl == nil {
+		lvl = LevelInfo
+	}
+	h := &CloudLoggingHandler{
+		out:       new(atomic.Value),
+		level:     lvl,
+		entryPool: &sync.Pool{New: func() any { return new(Entry) }},
+	}
+	h.out.Store(out)
+	return h
+}
+
+// setOutput replaces the Handler entries are passed to.
+func (h *CloudLoggingHandler) setOutput(out Handler) { h.out.Store(out) }
+
+// output returns the Handler entries are currently passed to.
+func (h *CloudLoggingHandler) output() Handler { return h.out.Load().(Handler) }
+
+// Enabled reports whether level is at or above the handler's minimum level.
+func (h *CloudLoggingHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle translates r into an Entry and passes it to the handler's output
+// Handler.
+// r.Time is routed onto Entry.Time, so the record's own timestamp is
+// preserved even if the Entry is written later by a buffering or async
+// Handler. Attributes named after a reserved Cloud Logging field (one of
+// "logging.googleapis.com/trace", "spanId", "trace_sampled", "labels",
+// "insertId" or "operation", or the top-level "httpRequest" and
+// "stack_trace") are routed onto the matching Entry field; every other
+// attribute, including those attached via Logger.With, is serialized as an
+// additional top-level JSON field.
+func (h *CloudLoggingHandler) Handle(_ context.Context, r slog.Record) error {
+	e := h.entryPool.Get().(*Entry)
+	*e = Entry{
+		Severity:       severityForLevel(r.Level).String(),
+		Time:           entryTime(r.Time),
+		SourceLocation: sourceLocation(r.PC),
+		Message:        r.Message,
+		extra:          e.extra[:0],
+	}
+
+	for _, a := range h.attrs {
+		h.routeAttr(e, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		h.routeAttr(e, a)
+		return true
+	})
+
+	// out may hand e off to a background goroutine (AsyncHandler) that
+	// outlives this call, so hand it a copy whose extra slice doesn't alias
+	// the pooled Entry's backing array before returning e to the pool.
+	out := *e
+	out.extra = append([]slog.Attr(nil), e.extra...)
+	h.entryPool.Put(e)
+
+	return h.output().Handle(out)
+}
+
+// routeAttr resolves a (calling its slog.LogValuer if it has one) and either
+// routes it onto the matching reserved Entry field, inlines it (per the
+// slog.Handler contract, if a is an empty-keyed group) or appends it to e's
+// extra fields.
+func (h *CloudLoggingHandler) routeAttr(e *Entry, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if a.Key == "" {
+			for _, ga := range group {
+				h.routeAttr(e, ga)
+			}
+			return
+		}
+		e.extra = append(e.extra, slog.Any(a.Key, groupToMap(group)))
+		return
+	}
+
+	switch a.Key {
+	case "logging.googleapis.com/trace":
+		e.Trace = a.Value.String()
+	case "logging.googleapis.com/spanId":
+		e.SpanID = a.Value.String()
+	case "logging.googleapis.com/trace_sampled":
+		e.TraceSampled, _ = a.Value.Any().(bool)
+	case "logging.googleapis.com/labels":
+		e.Labels, _ = a.Value.Any().(map[string]string)
+	case "logging.googleapis.com/insertId":
+		e.InsertID = a.Value.String()
+	case "logging.googleapis.com/operation":
+		e.Operation, _ = a.Value.Any().(*Operation)
+	case "httpRequest":
+		e.HTTPRequest, _ = a.Value.Any().(*HTTPRequest)
+	case "stack_trace":
+		e.StackTrace = a.Value.String()
+	default:
+		e.extra = append(e.extra, a)
+	}
+}
+
+// groupToMap resolves attrs (recursing into and inlining nested empty-keyed
+// groups per the slog.Handler contract) into the map a non-empty-keyed
+// group is serialized as.
+func groupToMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			m[a.Key] = v.Any()
+			continue
+		}
+		if a.Key == "" {
+			for k, vv := range groupToMap(v.Group()) {
+				m[k] = vv
+			}
+			continue
+		}
+		m[a.Key] = groupToMap(v.Group())
+	}
+	return m
+}
+
+// WithAttrs returns a new handler whose attributes consist of both the
+// receiver's attributes and attrs. Attached attributes are serialized as
+// additional top-level JSON fields on every subsequent entry.
+func (h *CloudLoggingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := &CloudLoggingHandler{
+		out:       new(atomic.Value),
+		level:     h.level,
+		attrs:     append(append([]slog.Attr{}, h.attrs...), attrs...),
+		entryPool: h.entryPool,
+	}
+	derived.out.Store(h.output())
+	return derived
+}
+
+// WithGroup returns the receiver unchanged: the flat Cloud Logging schema has
+// no place to nest a group, so group names are not reflected in the output.
+func (h *CloudLoggingHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
@@ -0,0 +1,160 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCloudLoggingHandlerHandle(t *testing.T) {
+	for _, c := range []struct {
+		level slog.Level
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			slog.LevelInfo,
+			nil,
+			`{"severity":"INFO","message":"hoge"}` + "\n",
+		},
+		{
+			slog.LevelInfo,
+			[]slog.Attr{
+				slog.String("logging.googleapis.com/trace", "trace"),
+				slog.String("logging.googleapis.com/spanId", "span-id"),
+				slog.String("user_id", "u1"),
+			},
+			`{"severity":"INFO","logging.googleapis.com/trace":"trace","logging.googleapis.com/spanId":"span-id","message":"hoge","user_id":"u1"}` + "\n",
+		},
+		{
+			LevelNotice,
+			nil,
+			`{"severity":"NOTICE","message":"hoge"}` + "\n",
+		},
+		{
+			slog.LevelError,
+			[]slog.Attr{
+				slog.Bool("logging.googleapis.com/trace_sampled", true),
+				slog.Any("logging.googleapis.com/labels", map[string]string{"env": "prod"}),
+				slog.String("logging.googleapis.com/insertId", "insert-1"),
+				slog.Any("logging.googleapis.com/operation", &Operation{ID: "op-1", Producer: "p", First: true}),
+				slog.Any("httpRequest", &HTTPRequest{RequestMethod: "GET", Status: 200}),
+				slog.String("stack_trace", "trace"),
+			},
+			`{"severity":"ERROR","httpRequest":{"requestMethod":"GET","status":200},"logging.googleapis.com/labels":{"env":"prod"},"logging.googleapis.com/insertId":"insert-1","logging.googleapis.com/operation":{"id":"op-1","producer":"p","first":true},"logging.googleapis.com/trace_sampled":true,"stack_trace":"trace","message":"hoge"}` + "\n",
+		},
+	} {
+		var buf bytes.Buffer
+		h := NewCloudLoggingHandler(&buf, nil)
+
+		r := slog.NewRecord(time.Time{}, c.level, "hoge", 0)
+		r.AddAttrs(c.attrs...)
+
+		if err := h.Handle(context.Background(), r); err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if got := buf.String(); got != c.want {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+	}
+}
+
+type secretValuer struct{ s string }
+
+func (v secretValuer) LogValue() slog.Value { return slog.StringValue(v.s) }
+
+func TestCloudLoggingHandlerHandleResolvesLogValuer(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudLoggingHandler(&buf, nil)
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hoge", 0)
+	r.AddAttrs(slog.Any("password", secretValuer{"hunter2"}))
+
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `{"severity":"INFO","message":"hoge","password":"hunter2"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestCloudLoggingHandlerHandleGroup(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudLoggingHandler(&buf, nil)
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hoge", 0)
+	r.AddAttrs(slog.Group("g", slog.String("a", "b"), slog.Int("c", 1)))
+
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `{"severity":"INFO","message":"hoge","g":{"a":"b","c":1}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestCloudLoggingHandlerHandleInlineGroup(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudLoggingHandler(&buf, nil)
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hoge", 0)
+	r.AddAttrs(slog.Group("", slog.String("user_id", "u1")))
+
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `{"severity":"INFO","message":"hoge","user_id":"u1"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestCloudLoggingHandlerHandleTime(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudLoggingHandler(&buf, nil)
+
+	r := slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelInfo, "hoge", 0)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `{"severity":"INFO","time":"2024-01-02T03:04:05Z","message":"hoge"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestCloudLoggingHandlerEnabled(t *testing.T) {
+	h := NewCloudLoggingHandler(&bytes.Buffer{}, &HandlerOptions{Level: LevelWarning})
+
+	for _, c := range []struct {
+		level slog.Level
+		want  bool
+	}{
+		{slog.LevelInfo, false},
+		{LevelWarning, true},
+		{slog.LevelError, true},
+	} {
+		if got := h.Enabled(context.Background(), c.level); got != c.want {
+			t.Errorf("level=%v, got=%v, want=%v", c.level, got, c.want)
+		}
+	}
+}
+
+func TestCloudLoggingHandlerWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudLoggingHandler(&buf, nil).WithAttrs([]slog.Attr{slog.String("user_id", "u1")})
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hoge", 0)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	want := `{"severity":"INFO","message":"hoge","user_id":"u1"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type ctxKey int
+
+const (
+	ctxKeyHTTPRequest ctxKey = iota
+	ctxKeyHTTPRequestState
+	ctxKeyLabels
+	ctxKeyOperation
+	ctxKeyInsertID
+	ctxKeyTraceContext
+)
+
+// WithHTTPRequest returns a copy of ctx carrying req/resp/latency as an
+// *HTTPRequest. Subsequent *WithCtx log calls made with the returned context
+// include it as the entry's httpRequest field.
+func WithHTTPRequest(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration) context.Context {
+	hr := &HTTPRequest{
+		RequestMethod: req.Method,
+		RequestURL:    req.URL.String(),
+		UserAgent:     req.UserAgent(),
+		RemoteIP:      req.RemoteAddr,
+		Latency:       latency.String(),
+	}
+	if resp != nil {
+		hr.Status = resp.StatusCode
+		hr.ResponseSize = resp.ContentLength
+	}
+	return context.WithValue(ctx, ctxKeyHTTPRequest, hr)
+}
+
+// WithLabels returns a copy of ctx carrying labels. Subsequent *WithCtx log
+// calls made with the returned context include them as the entry's
+// logging.googleapis.com/labels field.
+func WithLabels(ctx context.Context, labels map[string]string) context.Context {
+	return context.WithValue(ctx, ctxKeyLabels, labels)
+}
+
+// WithOperation returns a copy of ctx carrying operation metadata.
+// Subsequent *WithCtx log calls made with the returned context include it as
+// the entry's logging.googleapis.com/operation field.
+func WithOperation(ctx context.Context, id, producer string, first, last bool) context.Context {
+	return context.WithValue(ctx, ctxKeyOperation, &Operation{ID: id, Producer: producer, First: first, Last: last})
+}
+
+// WithInsertID returns a copy of ctx carrying id. Subsequent *WithCtx log
+// calls made with the returned context include it as the entry's
+// logging.googleapis.com/insertId field.
+func WithInsertID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyInsertID, id)
+}
+
+// statusRecorder wraps an http.ResponseWriter to observe the status code and
+// response size written so far.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// httpRequestState is the value stashed on the context by HTTPMiddleware. It
+// is resolved into an *HTTPRequest lazily so that status, response size and
+// latency reflect what has happened by the time a log call is made.
+type httpRequestState struct {
+	hr    *HTTPRequest
+	rec   *statusRecorder
+	start time.Time
+}
+
+// HTTPMiddleware attaches HTTP request metadata to the request context so
+// that *WithCtx log calls made while handling r automatically populate the
+// entry's httpRequest field. Status, response size and latency reflect what
+// has been written to the response at the time each log call is made.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		state := &httpRequestState{
+			hr: &HTTPRequest{
+				RequestMethod: r.Method,
+				RequestURL:    r.URL.String(),
+				UserAgent:     r.UserAgent(),
+				RemoteIP:      r.RemoteAddr,
+			},
+			rec:   rec,
+			start: time.Now(),
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKeyHTTPRequestState, state)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+	})
+}
+
+// httpRequestFromContext returns the *HTTPRequest attached to ctx by
+// WithHTTPRequest or HTTPMiddleware, or nil if there is none.
+func httpRequestFromContext(ctx context.Context) *HTTPRequest {
+	if state, ok := ctx.Value(ctxKeyHTTPRequestState).(*httpRequestState); ok {
+		hr := *state.hr
+		hr.Status = state.rec.status
+		hr.ResponseSize = state.rec.size
+		hr.Latency = time.Since(state.start).String()
+		return &hr
+	}
+	if hr, ok := ctx.Value(ctxKeyHTTPRequest).(*HTTPRequest); ok {
+		return hr
+	}
+	return nil
+}
+
+// ctxAttrs converts any httpRequest, labels, operation or insertId metadata
+// attached to ctx into slog attributes keyed by the matching Entry field's
+// JSON name, so that (*Logger).logWithSpan can fold them into the outgoing
+// record. defaultLabels, typically a Logger's own labels, are merged under
+// the labels attached to ctx.
+func ctxAttrs(ctx context.Context, defaultLabels map[string]string) []slog.Attr {
+	var attrs []slog.Attr
+	if hr := httpRequestFromContext(ctx); hr != nil {
+		attrs = append(attrs, slog.Any("httpRequest", hr))
+	}
+	if labels := mergeLabels(defaultLabels, ctxLabels(ctx)); len(labels) > 0 {
+		attrs = append(attrs, slog.Any("logging.googleapis.com/labels", labels))
+	}
+	if op, ok := ctx.Value(ctxKeyOperation).(*Operation); ok {
+		attrs = append(attrs, slog.Any("logging.googleapis.com/operation", op))
+	}
+	if id, ok := ctx.Value(ctxKeyInsertID).(string); ok {
+		attrs = append(attrs, slog.String("logging.googleapis.com/insertId", id))
+	}
+	return attrs
+}
+
+func ctxLabels(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(ctxKeyLabels).(map[string]string)
+	return labels
+}
+
+// mergeLabels returns the union of a and b, with b taking precedence on key
+// conflicts. Either map may be nil.
+func mergeLabels(a, b map[string]string) map[string]string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
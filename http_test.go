@@ -0,0 +1,53 @@
+package slog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithHTTPRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/path", nil)
+	resp := &http.Response{StatusCode: http.StatusTeapot, ContentLength: 42}
+
+	ctx := WithHTTPRequest(context.Background(), req, resp, 100*time.Millisecond)
+	hr := httpRequestFromContext(ctx)
+	if hr == nil {
+		t.Fatal("httpRequestFromContext returned nil")
+	}
+	if hr.RequestMethod != http.MethodGet || hr.RequestURL != req.URL.String() || hr.Status != http.StatusTeapot || hr.ResponseSize != 42 || hr.Latency != "100ms" {
+		t.Errorf("got=%+v", hr)
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	var got *HTTPRequest
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+		got = httpRequestFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/path", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got == nil {
+		t.Fatal("httpRequestFromContext returned nil")
+	}
+	if got.RequestMethod != http.MethodPost || got.Status != http.StatusCreated || got.ResponseSize != int64(len("hello")) {
+		t.Errorf("got=%+v", got)
+	}
+}
+
+func TestCtxAttrs(t *testing.T) {
+	ctx := WithLabels(context.Background(), map[string]string{"env": "test"})
+	ctx = WithOperation(ctx, "op-1", "producer", true, false)
+	ctx = WithInsertID(ctx, "insert-1")
+
+	attrs := ctxAttrs(ctx, nil)
+	if len(attrs) != 3 {
+		t.Fatalf("len(attrs)=%d, want=3", len(attrs))
+	}
+}
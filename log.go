@@ -1,16 +1,17 @@
 package slog
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"runtime"
+	"log/slog"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel/api/trace"
 )
@@ -21,47 +22,46 @@ var (
 	stateInitialized   uint32 = 1
 
 	mu sync.Mutex
-
-	projectID string
-	severity  Severity = SeverityDebug
 )
 
-// An Option is an option for a slog package.
-type Option func()
-
-// WithSeverity returns an Option that specifies a severity.
-// Default severity is SeverityDebug.
-func WithSeverity(s Severity) Option {
-	return func() { severity = s }
-}
-
-// WithLogLevel returns an Option that specifies a log level.
-func WithLogLevel(lvl string) Option {
-	return func() { severity = toSeverity(lvl) }
-}
-
 var errInitialized = errors.New("slog is already initialized")
 
-// Setup is setup function for slog package.
+// Setup constructs the package's default Logger and replaces std with it.
+// It may only be called once; later calls return errInitialized. To build
+// additional, independently configured Loggers, use New instead.
 func Setup(traceProjectID string, opts ...Option) error {
 	mu.Lock()
-	defer mu.Unlock()
-
 	if state != stateUninitialized {
+		mu.Unlock()
 		return errInitialized
 	}
-
-	projectID = traceProjectID
-	for _, opt := range opts {
-		opt()
-	}
 	state = stateInitialized
+	mu.Unlock()
 
+	std.Store(New(traceProjectID, opts...))
 	return nil
 }
 
 // Enabled decides whether a given logging level is enabled when logging a message.
-func Enabled(s Severity) bool { return s >= severity }
+func Enabled(s Severity) bool { return std.Load().(*Logger).Enabled(s) }
+
+// Shutdown flushes and closes the default Logger's Handler, draining any
+// entries still in flight. The default Logger's Handler is the WriterHandler
+// built by Setup (or at package init), which does not buffer; no
+// package-level function replaces it.
+func Shutdown(ctx context.Context) error { return std.Load().(*Logger).Shutdown(ctx) }
+
+// NewLogger returns a *slog.Logger backed by a CloudLoggingHandler, using the
+// same options as New.
+func NewLogger(opts ...Option) *slog.Logger {
+	return New("", opts...).sl
+}
+
+// std is the Logger used by the package-level logging functions below.
+// Setup replaces it once options have been applied.
+var std atomic.Value
+
+func init() { std.Store(New("")) }
 
 // Severity is implementation of https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogSeverity.
 type Severity uint32
@@ -101,11 +101,38 @@ func toSeverity(lvl string) Severity {
 	return SeverityDefault
 }
 
+// level returns the slog.Level that a CloudLoggingHandler maps back to s.
+func (s Severity) level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return LevelDebug
+	case SeverityInfo:
+		return LevelInfo
+	case SeverityNotice:
+		return LevelNotice
+	case SeverityWarning:
+		return LevelWarning
+	case SeverityError:
+		return LevelError
+	case SeverityCritical:
+		return LevelCritical
+	case SeverityAlert:
+		return LevelAlert
+	case SeverityEmergency:
+		return LevelEmergency
+	default:
+		return LevelDebug - 1
+	}
+}
+
 // Entry is a log entry.
 // See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry.
 type Entry struct {
 	// A severity as string.
 	Severity string `json:"severity"`
+	// Optional. The time the log entry was created, in RFC 3339 format. If
+	// empty, Cloud Logging assigns the time the entry was received instead.
+	Time string `json:"time,omitempty"`
 	// Optional. Resource name of the trace associated with the log entry, if any.
 	// If it contains a relative resource name, the name is assumed to be relative to //tracing.googleapis.com.
 	// Example: projects/my-projectid/traces/06796866738c859f2f19b7cfb3214824
@@ -116,8 +143,61 @@ type Entry struct {
 	SpanID string `json:"logging.googleapis.com/spanId,omitempty"`
 	// Optional. Source code location information associated with the log entry, if any.
 	SourceLocation *SourceLocation `json:"logging.googleapis.com/sourceLocation,omitempty"`
+	// Optional. Information about the HTTP request associated with the log entry, if any.
+	HTTPRequest *HTTPRequest `json:"httpRequest,omitempty"`
+	// Optional. A map of key, value pairs that provides additional information about the log entry.
+	Labels map[string]string `json:"logging.googleapis.com/labels,omitempty"`
+	// Optional. A unique identifier for the log entry. Used to deduplicate entries with the same timestamp.
+	InsertID string `json:"logging.googleapis.com/insertId,omitempty"`
+	// Optional. Information about an operation associated with the log entry, if any.
+	Operation *Operation `json:"logging.googleapis.com/operation,omitempty"`
+	// Optional. The sampling decision for the trace associated with the log entry, if any.
+	TraceSampled bool `json:"logging.googleapis.com/trace_sampled,omitempty"`
+	// Optional. Stack trace associated with the log entry, used by Error Reporting to recognize the entry
+	// as an error report. See https://cloud.google.com/error-reporting/docs/formatting-error-messages.
+	StackTrace string `json:"stack_trace,omitempty"`
 	// The log entry payload, represented as a Unicode string (UTF-8).
 	Message string `json:"message"`
+
+	// extra holds attributes with no reserved Cloud Logging field, serialized
+	// as additional top-level JSON fields by write. Unexported so it is
+	// skipped by json.Marshal and populated only by CloudLoggingHandler.
+	extra []slog.Attr
+}
+
+// HTTPRequest is a subset of
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+// populated by WithHTTPRequest and HTTPMiddleware.
+type HTTPRequest struct {
+	// The request method, such as GET, HEAD, PUT, POST.
+	RequestMethod string `json:"requestMethod,omitempty"`
+	// The scheme (http, https), the host name, the path and the query portion of the URL that was requested.
+	RequestURL string `json:"requestUrl,omitempty"`
+	// The size of the HTTP response message in bytes, as a string.
+	ResponseSize int64 `json:"responseSize,omitempty,string"`
+	// The HTTP response status code.
+	Status int `json:"status,omitempty"`
+	// The user agent sent by the client.
+	UserAgent string `json:"userAgent,omitempty"`
+	// The IP address (IPv4 or IPv6) of the client that issued the HTTP request.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	// The request processing latency, such as "3.5s".
+	Latency string `json:"latency,omitempty"`
+}
+
+// Operation is additional information about a potentially long-running operation with which a
+// series of log entries is associated.
+type Operation struct {
+	// An arbitrary operation identifier, unique within the log entry's project, that groups the
+	// entries belonging to a single operation.
+	ID string `json:"id,omitempty"`
+	// An arbitrary producer identifier, such as a fully qualified class or module name, that
+	// distinguishes operation identifiers from different producers.
+	Producer string `json:"producer,omitempty"`
+	// Set to true if this is the first log entry in the operation.
+	First bool `json:"first,omitempty"`
+	// Set to true if this is the last log entry in the operation.
+	Last bool `json:"last,omitempty"`
 }
 
 // SourceLocation is additional information about the source code location that produced the log entry.
@@ -135,290 +215,306 @@ type SourceLocation struct {
 	Function string `json:"function,omitempty"`
 }
 
-func location(skip int) *SourceLocation {
-	pc, file, line, ok := runtime.Caller(skip + 1)
-	if !ok {
-		return nil
-	}
-
-	var function string
-	if f := runtime.FuncForPC(pc); f != nil {
-		function = f.Name()
-	}
-
-	return &SourceLocation{
-		File:     file,
-		Line:     int64(line),
-		Function: function,
+// write serializes entry as a single line of JSON, followed by a newline.
+// Any attrs are merged in as additional top-level fields.
+func write(w io.Writer, entry Entry, attrs ...slog.Attr) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if len(attrs) > 0 {
+		var buf bytes.Buffer
+		buf.Write(b[:len(b)-1])
+		for _, a := range attrs {
+			k, err := json.Marshal(a.Key)
+			if err != nil {
+				return err
+			}
+			v, err := json.Marshal(a.Value.Any())
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(',')
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+		b = buf.Bytes()
 	}
-}
-
-func write(w io.Writer, entry Entry) error {
-	return json.NewEncoder(w).Encode(entry)
-}
 
-func log(s Severity, msg string) error {
-	return write(os.Stdout, Entry{
-		Severity:       s.String(),
-		SourceLocation: location(2),
-		Message:        msg,
-	})
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
 }
 
 // Debug logs a message at SeverityDebug.
 func Debug(msg string) (err error) {
-	if Enabled(SeverityDebug) {
-		return log(SeverityDebug, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.log(SeverityDebug, msg)
 	}
 	return
 }
 
 // Debugf logs a message at SeverityDebug.
 func Debugf(format string, a ...interface{}) (err error) {
-	if Enabled(SeverityDebug) {
-		return log(SeverityDebug, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.log(SeverityDebug, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // Info logs a message at SeverityInfo.
 func Info(msg string) (err error) {
-	if Enabled(SeverityInfo) {
-		return log(SeverityInfo, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.log(SeverityInfo, msg)
 	}
 	return
 }
 
 // Infof logs a message at SeverityInfo.
 func Infof(format string, a ...interface{}) (err error) {
-	if Enabled(SeverityInfo) {
-		return log(SeverityInfo, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.log(SeverityInfo, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // Warn logs a message at SeverityWarning.
 func Warn(msg string) (err error) {
-	if Enabled(SeverityWarning) {
-		return log(SeverityWarning, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.log(SeverityWarning, msg)
 	}
 	return
 }
 
 // Warnf logs a message at SeverityWarning.
 func Warnf(format string, a ...interface{}) (err error) {
-	if Enabled(SeverityWarning) {
-		return log(SeverityWarning, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.log(SeverityWarning, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // Error logs a message at SeverityError.
 func Error(msg string) (err error) {
-	if Enabled(SeverityError) {
-		return log(SeverityError, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, msg)
 	}
 	return
 }
 
 // Errorf logs a message at SeverityError.
 func Errorf(format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return log(SeverityError, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
-// ReportError outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportError outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportError(msg string) (err error) {
-	if Enabled(SeverityError) {
-		return log(SeverityError, fmt.Sprintf("%s\n%s", msg, string(debug.Stack())))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, msg, slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
 
-// ReportErrorf outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportErrorf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportErrorf(format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return log(SeverityError, fmt.Sprintf(format+"\n%s", append(a, string(debug.Stack()))))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
 
-func logWithSpan(s Severity, span trace.Span, msg string) error {
-	if spanCtx := span.SpanContext(); span.IsRecording() && spanCtx.HasTraceID() && spanCtx.HasSpanID() {
-		return write(os.Stdout, Entry{
-			Severity:       s.String(),
-			Trace:          fmt.Sprintf("projects/%s/traces/%s", projectID, spanCtx.TraceID.String()),
-			SpanID:         spanCtx.SpanID.String(),
-			SourceLocation: location(2),
-			Message:        msg,
-		})
-	} else {
-		return write(os.Stdout, Entry{
-			Severity:       s.String(),
-			SourceLocation: location(2),
-			Message:        msg,
-		})
-	}
-}
-
 // DebugWithSpan logs a message at SeverityDebug.
 func DebugWithSpan(span trace.Span, msg string) (err error) {
-	if Enabled(SeverityDebug) {
-		return logWithSpan(SeverityDebug, span, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(context.Background(), SeverityDebug, span, msg)
 	}
 	return
 }
 
 // DebugWithSpanf logs a message at SeverityDebug.
 func DebugWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityDebug) {
-		return logWithSpan(SeverityDebug, span, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(context.Background(), SeverityDebug, span, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // InfoWithSpan logs a message at SeverityInfo.
 func InfoWithSpan(span trace.Span, msg string) (err error) {
-	if Enabled(SeverityInfo) {
-		return logWithSpan(SeverityInfo, span, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(context.Background(), SeverityInfo, span, msg)
 	}
 	return
 }
 
 // InfoWithSpanf logs a message at SeverityInfo.
 func InfoWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityInfo) {
-		return logWithSpan(SeverityInfo, span, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(context.Background(), SeverityInfo, span, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // WarnWithSpan logs a message at SeverityWarning.
 func WarnWithSpan(span trace.Span, msg string) (err error) {
-	if Enabled(SeverityWarning) {
-		return logWithSpan(SeverityWarning, span, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(context.Background(), SeverityWarning, span, msg)
 	}
 	return
 }
 
 // WarnWithSpanf logs a message at SeverityWarning.
 func WarnWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityWarning) {
-		return logWithSpan(SeverityWarning, span, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(context.Background(), SeverityWarning, span, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // ErrorWithSpan logs a message at SeverityError.
 func ErrorWithSpan(span trace.Span, msg string) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, span, msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, msg)
 	}
 	return
 }
 
 // ErrorWithSpanf logs a message at SeverityError.
 func ErrorWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, span, fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, fmt.Sprintf(format, a...))
 	}
 	return
 }
 
-// ReportErrorWithSpan outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportErrorWithSpan outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportErrorWithSpan(span trace.Span, msg string) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, span, fmt.Sprintf("%s\n%s", msg, string(debug.Stack())))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, msg, slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
 
-// ReportErrorWithSpanf outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportErrorWithSpanf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportErrorWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, span, fmt.Sprintf(format+"\n%s", append(a, string(debug.Stack()))))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
 
 // DebugWithCtx logs a message at SeverityDebug.
 func DebugWithCtx(ctx context.Context, msg string) (err error) {
-	if Enabled(SeverityDebug) {
-		return logWithSpan(SeverityDebug, trace.SpanFromContext(ctx), msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(ctx, SeverityDebug, trace.SpanFromContext(ctx), msg)
 	}
 	return
 }
 
 // DebugWithCtxf logs a message at SeverityDebug.
 func DebugWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityDebug) {
-		return logWithSpan(SeverityDebug, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(ctx, SeverityDebug, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // InfoWithCtx logs a message at SeverityInfo.
 func InfoWithCtx(ctx context.Context, msg string) (err error) {
-	if Enabled(SeverityInfo) {
-		return logWithSpan(SeverityInfo, trace.SpanFromContext(ctx), msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(ctx, SeverityInfo, trace.SpanFromContext(ctx), msg)
 	}
 	return
 }
 
 // InfoWithCtxf logs a message at SeverityInfo.
 func InfoWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityInfo) {
-		return logWithSpan(SeverityInfo, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(ctx, SeverityInfo, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // WarnWithCtx logs a message at SeverityWarning.
 func WarnWithCtx(ctx context.Context, msg string) (err error) {
-	if Enabled(SeverityWarning) {
-		return logWithSpan(SeverityWarning, trace.SpanFromContext(ctx), msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(ctx, SeverityWarning, trace.SpanFromContext(ctx), msg)
 	}
 	return
 }
 
 // WarnWithCtxf logs a message at SeverityWarning.
 func WarnWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityWarning) {
-		return logWithSpan(SeverityWarning, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(ctx, SeverityWarning, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
 	}
 	return
 }
 
 // ErrorWithCtx logs a message at SeverityError.
 func ErrorWithCtx(ctx context.Context, msg string) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, trace.SpanFromContext(ctx), msg)
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), msg)
 	}
 	return
 }
 
 // ErrorWithCtxf logs a message at SeverityError.
 func ErrorWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
 	}
 	return
 }
 
-// ReportErrorWithCtx outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportErrorWithCtx outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportErrorWithCtx(ctx context.Context, msg string) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf("%s\n%s", msg, string(debug.Stack())))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), msg, slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
 
-// ReportErrorWithCtx outputs a log with stacktrace so that error reporting can recognize the error.
+// ReportErrorWithCtxf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
 func ReportErrorWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
-	if Enabled(SeverityError) {
-		return logWithSpan(SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format+"\n%s", append(a, string(debug.Stack()))))
+	l := std.Load().(*Logger)
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
 	}
 	return
 }
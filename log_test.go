@@ -20,7 +20,7 @@ func TestSetup(t *testing.T) {
 }
 
 func TestEnabled(t *testing.T) {
-	severity = SeverityInfo
+	std.Load().(*Logger).level.Set(SeverityInfo.level())
 
 	for _, c := range []struct {
 		in   Severity
@@ -68,6 +68,10 @@ func TestLog(t *testing.T) {
 			Entry{Severity: SeverityInfo.String(), Trace: "trace", SpanID: "span-id", SourceLocation: &SourceLocation{File: "log_test.go", Line: 65, Function: "main.TestLog"}, Message: "fuga"},
 			`{"severity":"INFO","logging.googleapis.com/trace":"trace","logging.googleapis.com/spanId":"span-id","logging.googleapis.com/sourceLocation":{"file":"log_test.go","line":65,"function":"main.TestLog"},"message":"fuga"}` + "\n",
 		},
+		{
+			Entry{Severity: SeverityInfo.String(), Time: "2024-01-02T03:04:05Z", Message: "fuga"},
+			`{"severity":"INFO","time":"2024-01-02T03:04:05Z","message":"fuga"}` + "\n",
+		},
 	} {
 		var buf bytes.Buffer
 		write(&buf, c.in)
@@ -0,0 +1,383 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// loggerConfig accumulates the Options passed to New before a Logger is built.
+type loggerConfig struct {
+	severity Severity
+	w        io.Writer
+	labels   map[string]string
+	clock    func() time.Time
+}
+
+// An Option configures a Logger constructed by New, NewLogger or Setup.
+type Option func(*loggerConfig)
+
+// WithSeverity returns an Option that specifies a severity.
+// Default severity is SeverityDebug.
+func WithSeverity(s Severity) Option {
+	return func(c *loggerConfig) { c.severity = s }
+}
+
+// WithLogLevel returns an Option that specifies a log level.
+func WithLogLevel(lvl string) Option {
+	return func(c *loggerConfig) { c.severity = toSeverity(lvl) }
+}
+
+// WithWriter returns an Option that sets the writer a Logger writes entries
+// to. The default is os.Stdout.
+func WithWriter(w io.Writer) Option {
+	return func(c *loggerConfig) { c.w = w }
+}
+
+// WithDefaultLabels returns an Option that attaches labels to every entry a
+// Logger writes, in addition to any attached to a specific call via
+// WithLabels.
+func WithDefaultLabels(labels map[string]string) Option {
+	return func(c *loggerConfig) { c.labels = labels }
+}
+
+// WithClock returns an Option that overrides the func a Logger calls to
+// timestamp entries. It is mainly useful in tests. The default is time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(c *loggerConfig) { c.clock = clock }
+}
+
+// Logger writes Cloud Logging structured entries for a single Cloud Trace
+// project. Unlike the package-level functions, which share the single
+// default Logger configured by Setup, any number of Loggers with independent
+// severity thresholds, outputs, default labels and clocks can coexist in the
+// same process.
+type Logger struct {
+	projectID string
+	labels    map[string]string
+	clock     func() time.Time
+
+	level   *slog.LevelVar
+	handler *CloudLoggingHandler
+	sl      *slog.Logger
+}
+
+// New returns a Logger that attributes trace fields to traceProjectID.
+func New(traceProjectID string, opts ...Option) *Logger {
+	cfg := &loggerConfig{severity: SeverityDebug, w: os.Stdout, clock: time.Now}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	lvl := new(slog.LevelVar)
+	lvl.Set(cfg.severity.level())
+
+	l := &Logger{projectID: traceProjectID, labels: cfg.labels, clock: cfg.clock, level: lvl}
+	l.handler = NewCloudLoggingHandler(cfg.w, &HandlerOptions{Level: lvl})
+	l.sl = slog.New(l.handler)
+	return l
+}
+
+// Enabled decides whether a given logging level is enabled when logging a message.
+func (l *Logger) Enabled(s Severity) bool { return s.level() >= l.level.Level() }
+
+// SetHandler replaces the Handler that l's entries are written to, taking
+// effect for subsequent log calls. The previous handler is not closed; the
+// caller remains responsible for it.
+func (l *Logger) SetHandler(h Handler) { l.handler.setOutput(h) }
+
+// Shutdown flushes and closes l's Handler, blocking until any entries still
+// in flight have been written or ctx is done, whichever comes first. l must
+// not be used to log after Shutdown returns.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- l.handler.output().Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Logger) log(s Severity, msg string, attrs ...slog.Attr) error {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(l.clock(), s.level(), msg, pcs[0])
+	if len(l.labels) > 0 {
+		r.AddAttrs(slog.Any("logging.googleapis.com/labels", l.labels))
+	}
+	r.AddAttrs(attrs...)
+	return l.handler.Handle(context.Background(), r)
+}
+
+func (l *Logger) logWithSpan(ctx context.Context, s Severity, span trace.Span, msg string, attrs ...slog.Attr) error {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(l.clock(), s.level(), msg, pcs[0])
+
+	if spanCtx := span.SpanContext(); span.IsRecording() && spanCtx.HasTraceID() && spanCtx.HasSpanID() {
+		r.AddAttrs(
+			slog.String("logging.googleapis.com/trace", fmt.Sprintf("projects/%s/traces/%s", l.projectID, spanCtx.TraceID.String())),
+			slog.String("logging.googleapis.com/spanId", spanCtx.SpanID.String()),
+			slog.Bool("logging.googleapis.com/trace_sampled", spanCtx.IsSampled()),
+		)
+	} else if tc, ok := traceContextFromContext(ctx); ok {
+		r.AddAttrs(
+			slog.String("logging.googleapis.com/trace", fmt.Sprintf("projects/%s/traces/%s", l.projectID, tc.traceID)),
+			slog.String("logging.googleapis.com/spanId", tc.spanID),
+			slog.Bool("logging.googleapis.com/trace_sampled", tc.sampled),
+		)
+	}
+	r.AddAttrs(ctxAttrs(ctx, l.labels)...)
+	r.AddAttrs(attrs...)
+
+	return l.handler.Handle(ctx, r)
+}
+
+// Debug logs a message at SeverityDebug.
+func (l *Logger) Debug(msg string) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.log(SeverityDebug, msg)
+	}
+	return
+}
+
+// Debugf logs a message at SeverityDebug.
+func (l *Logger) Debugf(format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.log(SeverityDebug, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// Info logs a message at SeverityInfo.
+func (l *Logger) Info(msg string) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.log(SeverityInfo, msg)
+	}
+	return
+}
+
+// Infof logs a message at SeverityInfo.
+func (l *Logger) Infof(format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.log(SeverityInfo, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// Warn logs a message at SeverityWarning.
+func (l *Logger) Warn(msg string) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.log(SeverityWarning, msg)
+	}
+	return
+}
+
+// Warnf logs a message at SeverityWarning.
+func (l *Logger) Warnf(format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.log(SeverityWarning, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// Error logs a message at SeverityError.
+func (l *Logger) Error(msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, msg)
+	}
+	return
+}
+
+// Errorf logs a message at SeverityError.
+func (l *Logger) Errorf(format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// ReportError outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportError(msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, msg, slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
+
+// ReportErrorf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportErrorf(format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.log(SeverityError, fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
+
+// DebugWithSpan logs a message at SeverityDebug.
+func (l *Logger) DebugWithSpan(span trace.Span, msg string) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(context.Background(), SeverityDebug, span, msg)
+	}
+	return
+}
+
+// DebugWithSpanf logs a message at SeverityDebug.
+func (l *Logger) DebugWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(context.Background(), SeverityDebug, span, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// InfoWithSpan logs a message at SeverityInfo.
+func (l *Logger) InfoWithSpan(span trace.Span, msg string) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(context.Background(), SeverityInfo, span, msg)
+	}
+	return
+}
+
+// InfoWithSpanf logs a message at SeverityInfo.
+func (l *Logger) InfoWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(context.Background(), SeverityInfo, span, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// WarnWithSpan logs a message at SeverityWarning.
+func (l *Logger) WarnWithSpan(span trace.Span, msg string) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(context.Background(), SeverityWarning, span, msg)
+	}
+	return
+}
+
+// WarnWithSpanf logs a message at SeverityWarning.
+func (l *Logger) WarnWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(context.Background(), SeverityWarning, span, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// ErrorWithSpan logs a message at SeverityError.
+func (l *Logger) ErrorWithSpan(span trace.Span, msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, msg)
+	}
+	return
+}
+
+// ErrorWithSpanf logs a message at SeverityError.
+func (l *Logger) ErrorWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// ReportErrorWithSpan outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportErrorWithSpan(span trace.Span, msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, msg, slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
+
+// ReportErrorWithSpanf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportErrorWithSpanf(span trace.Span, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(context.Background(), SeverityError, span, fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
+
+// DebugWithCtx logs a message at SeverityDebug.
+func (l *Logger) DebugWithCtx(ctx context.Context, msg string) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(ctx, SeverityDebug, trace.SpanFromContext(ctx), msg)
+	}
+	return
+}
+
+// DebugWithCtxf logs a message at SeverityDebug.
+func (l *Logger) DebugWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityDebug) {
+		return l.logWithSpan(ctx, SeverityDebug, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// InfoWithCtx logs a message at SeverityInfo.
+func (l *Logger) InfoWithCtx(ctx context.Context, msg string) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(ctx, SeverityInfo, trace.SpanFromContext(ctx), msg)
+	}
+	return
+}
+
+// InfoWithCtxf logs a message at SeverityInfo.
+func (l *Logger) InfoWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityInfo) {
+		return l.logWithSpan(ctx, SeverityInfo, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// WarnWithCtx logs a message at SeverityWarning.
+func (l *Logger) WarnWithCtx(ctx context.Context, msg string) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(ctx, SeverityWarning, trace.SpanFromContext(ctx), msg)
+	}
+	return
+}
+
+// WarnWithCtxf logs a message at SeverityWarning.
+func (l *Logger) WarnWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityWarning) {
+		return l.logWithSpan(ctx, SeverityWarning, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// ErrorWithCtx logs a message at SeverityError.
+func (l *Logger) ErrorWithCtx(ctx context.Context, msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), msg)
+	}
+	return
+}
+
+// ErrorWithCtxf logs a message at SeverityError.
+func (l *Logger) ErrorWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...))
+	}
+	return
+}
+
+// ReportErrorWithCtx outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportErrorWithCtx(ctx context.Context, msg string) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), msg, slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
+
+// ReportErrorWithCtxf outputs a log with a stack_trace field so that Error Reporting can recognize the error.
+func (l *Logger) ReportErrorWithCtxf(ctx context.Context, format string, a ...interface{}) (err error) {
+	if l.Enabled(SeverityError) {
+		return l.logWithSpan(ctx, SeverityError, trace.SpanFromContext(ctx), fmt.Sprintf(format, a...), slog.String("stack_trace", string(debug.Stack())))
+	}
+	return
+}
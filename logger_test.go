@@ -0,0 +1,60 @@
+package slog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNewLoggerIndependentConfig(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+
+	l1 := New("proj1",
+		WithSeverity(SeverityWarning),
+		WithWriter(&buf1),
+		WithClock(func() time.Time { return time.Time{} }),
+	)
+	l2 := New("proj2",
+		WithSeverity(SeverityDebug),
+		WithWriter(&buf2),
+		WithClock(func() time.Time { return time.Time{} }),
+		WithDefaultLabels(map[string]string{"env": "test"}),
+	)
+
+	if l1.Enabled(SeverityInfo) {
+		t.Errorf("l1.Enabled(SeverityInfo)=true, want=false")
+	}
+	if !l2.Enabled(SeverityInfo) {
+		t.Errorf("l2.Enabled(SeverityInfo)=false, want=true")
+	}
+
+	l1.Info("should be dropped")
+	if buf1.Len() != 0 {
+		t.Errorf("buf1=%q, want empty", buf1.String())
+	}
+
+	l2.Info("hoge")
+	got := buf2.String()
+	for _, want := range []string{`"severity":"INFO"`, `"message":"hoge"`, `"logging.googleapis.com/labels":{"env":"test"}`} {
+		if !bytes.Contains([]byte(got), []byte(want)) {
+			t.Errorf("got=%v, want substring=%v", got, want)
+		}
+	}
+}
+
+func TestLoggerReportError(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithClock(func() time.Time { return time.Time{} }))
+
+	if err := l.ReportError("boom"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	got := buf.String()
+	if !bytes.Contains([]byte(got), []byte(`"message":"boom"`)) {
+		t.Errorf("got=%v, want message=%q unmodified", got, "boom")
+	}
+	if !bytes.Contains([]byte(got), []byte(`"stack_trace":"goroutine`)) {
+		t.Errorf("got=%v, want a stack_trace field holding a stack dump", got)
+	}
+}
@@ -0,0 +1,221 @@
+package slog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Handler writes Entry values to their final destination. Unlike
+// slog.Handler, which CloudLoggingHandler implements to translate a
+// slog.Record into an Entry, a Handler deals only in already-translated
+// Entry values, which makes it straightforward to compose (buffering,
+// fanning out, batching) independently of slog.
+type Handler interface {
+	// Handle writes e. Implementations that buffer or batch entries may
+	// return before e has reached its destination.
+	Handle(e Entry) error
+	// Flush blocks until any entries buffered by Handle have been written.
+	Flush() error
+	// Close flushes and releases any resources held by the handler. The
+	// handler must not be used after Close returns.
+	Close() error
+}
+
+// WriterHandler is a Handler that writes every Entry to w as it is handled,
+// serialized by a mutex so that concurrent writers don't interleave.
+type WriterHandler struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterHandler returns a WriterHandler that writes to w.
+func NewWriterHandler(w io.Writer) *WriterHandler {
+	return &WriterHandler{mu: new(sync.Mutex), w: w}
+}
+
+// Handle writes e to the underlying writer.
+func (h *WriterHandler) Handle(e Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return write(h.w, e, e.extra...)
+}
+
+// Flush is a no-op: WriterHandler never buffers entries.
+func (h *WriterHandler) Flush() error { return nil }
+
+// Close is a no-op: WriterHandler holds no resources beyond its writer,
+// which it does not own.
+func (h *WriterHandler) Close() error { return nil }
+
+// OverflowPolicy controls what an AsyncHandler does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Handle block until buffer space is available.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop makes Handle drop the entry and return immediately.
+	OverflowDrop
+)
+
+// asyncMsg is either an Entry to write or a request to synchronize with the
+// background goroutine, queued on the same channel so it is processed after
+// every entry enqueued before it.
+type asyncMsg struct {
+	entry Entry
+	sync  chan struct{}
+}
+
+var errAsyncHandlerClosed = errors.New("async handler is closed")
+
+// AsyncHandler buffers entries in a channel and writes them to next from a
+// single background goroutine, so that Handle never blocks on next's I/O.
+type AsyncHandler struct {
+	next    Handler
+	policy  OverflowPolicy
+	msgs    chan asyncMsg
+	done    chan struct{}
+	dropped uint64
+
+	// mu guards closed against a concurrent Close: Handle and Flush hold it
+	// for reading while they send on msgs, and Close takes it for writing
+	// before closing msgs, so a send can never race a close of the channel
+	// it sends on.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncHandler returns an AsyncHandler that writes to next from a
+// background goroutine, buffering up to size entries. When the buffer is
+// full, Handle's behavior is governed by policy.
+func NewAsyncHandler(next Handler, size int, policy OverflowPolicy) *AsyncHandler {
+	h := &AsyncHandler{
+		next:   next,
+		policy: policy,
+		msgs:   make(chan asyncMsg, size),
+		done:   make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.done)
+	for m := range h.msgs {
+		if m.sync != nil {
+			close(m.sync)
+			continue
+		}
+		h.next.Handle(m.entry)
+	}
+}
+
+// Handle enqueues e to be written by the background goroutine. If the
+// buffer is full, Handle blocks or drops e according to the handler's
+// OverflowPolicy. It returns errAsyncHandlerClosed if Close has already been
+// called.
+func (h *AsyncHandler) Handle(e Entry) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return errAsyncHandlerClosed
+	}
+
+	if h.policy == OverflowDrop {
+		select {
+		case h.msgs <- asyncMsg{entry: e}:
+		default:
+			atomic.AddUint64(&h.dropped, 1)
+		}
+		return nil
+	}
+	h.msgs <- asyncMsg{entry: e}
+	return nil
+}
+
+// Dropped returns the number of entries dropped so far because the buffer
+// was full. It is always zero unless the handler's OverflowPolicy is
+// OverflowDrop.
+func (h *AsyncHandler) Dropped() uint64 { return atomic.LoadUint64(&h.dropped) }
+
+// Flush blocks until every entry enqueued before the call to Flush has been
+// passed to next, then flushes next. It returns errAsyncHandlerClosed if
+// Close has already been called.
+func (h *AsyncHandler) Flush() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return errAsyncHandlerClosed
+	}
+
+	sync := make(chan struct{})
+	h.msgs <- asyncMsg{sync: sync}
+	<-sync
+	return h.next.Flush()
+}
+
+// Close drains any entries still buffered, waits for any Handle or Flush
+// call already in flight to finish enqueueing, stops the background
+// goroutine and closes next. Close is idempotent.
+func (h *AsyncHandler) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.msgs)
+	<-h.done
+	return h.next.Close()
+}
+
+// FanOutHandler is a Handler that writes every Entry to each of a fixed set
+// of child handlers.
+type FanOutHandler struct {
+	handlers []Handler
+}
+
+// NewFanOutHandler returns a FanOutHandler that writes to every handler in
+// handlers.
+func NewFanOutHandler(handlers ...Handler) *FanOutHandler {
+	return &FanOutHandler{handlers: handlers}
+}
+
+// Handle writes e to every child handler and, after attempting all of them,
+// returns the errors encountered joined with errors.Join (nil if none).
+func (h *FanOutHandler) Handle(e Entry) error {
+	var errs []error
+	for _, c := range h.handlers {
+		if err := c.Handle(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flushes every child handler and, after attempting all of them,
+// returns the errors encountered joined with errors.Join (nil if none).
+func (h *FanOutHandler) Flush() error {
+	var errs []error
+	for _, c := range h.handlers {
+		if err := c.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every child handler and, after attempting all of them,
+// returns the errors encountered joined with errors.Join (nil if none).
+func (h *FanOutHandler) Close() error {
+	var errs []error
+	for _, c := range h.handlers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,164 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriterHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewWriterHandler(&buf)
+
+	if err := h.Handle(Entry{Severity: "INFO", Message: "hoge"}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `{"severity":"INFO","message":"hoge"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+	if err := h.Flush(); err != nil {
+		t.Errorf("Flush err=%v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("Close err=%v", err)
+	}
+}
+
+func TestAsyncHandlerBlock(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewWriterHandler(&buf), 1, OverflowBlock)
+
+	for i := 0; i < 10; i++ {
+		if err := h.Handle(Entry{Severity: "INFO", Message: "hoge"}); err != nil {
+			t.Fatalf("err=%v", err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close err=%v", err)
+	}
+
+	if got, want := bytes.Count(buf.Bytes(), []byte("hoge")), 10; got != want {
+		t.Errorf("got=%d entries, want=%d", got, want)
+	}
+}
+
+func TestAsyncHandlerDrop(t *testing.T) {
+	block := make(chan struct{})
+	h := NewAsyncHandler(blockingHandler{block}, 1, OverflowDrop)
+
+	for i := 0; i < 10; i++ {
+		if err := h.Handle(Entry{Severity: "INFO", Message: "hoge"}); err != nil {
+			t.Fatalf("err=%v", err)
+		}
+	}
+	close(block)
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close err=%v", err)
+	}
+
+	if h.Dropped() == 0 {
+		t.Errorf("Dropped()=0, want>0")
+	}
+}
+
+func TestAsyncHandlerFlush(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewWriterHandler(&buf), 4, OverflowBlock)
+
+	h.Handle(Entry{Severity: "INFO", Message: "hoge"})
+	if err := h.Flush(); err != nil {
+		t.Fatalf("Flush err=%v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("hoge")) {
+		t.Errorf("buf=%q, want to contain %q", buf.String(), "hoge")
+	}
+	h.Close()
+}
+
+func TestAsyncHandlerConcurrentHandleAndClose(t *testing.T) {
+	h := NewAsyncHandler(NewWriterHandler(&bytes.Buffer{}), 4, OverflowBlock)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				h.Handle(Entry{Severity: "INFO", Message: "hoge"})
+			}
+		}
+	}()
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close err=%v", err)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestFanOutHandler(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	h := NewFanOutHandler(NewWriterHandler(&buf1), NewWriterHandler(&buf2))
+
+	if err := h.Handle(Entry{Severity: "INFO", Message: "hoge"}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	for _, buf := range []*bytes.Buffer{&buf1, &buf2} {
+		if !bytes.Contains(buf.Bytes(), []byte("hoge")) {
+			t.Errorf("buf=%q, want to contain %q", buf.String(), "hoge")
+		}
+	}
+
+	if err := h.Close(); err != nil {
+		t.Errorf("Close err=%v", err)
+	}
+}
+
+func TestFanOutHandlerError(t *testing.T) {
+	errBoom := errors.New("boom")
+	h := NewFanOutHandler(erroringHandler{errBoom}, NewWriterHandler(&bytes.Buffer{}))
+
+	if err := h.Handle(Entry{Severity: "INFO", Message: "hoge"}); !errors.Is(err, errBoom) {
+		t.Errorf("err=%v, want to wrap %v", err, errBoom)
+	}
+}
+
+func TestLoggerSetHandlerAndShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("proj", WithWriter(&buf), WithClock(func() time.Time { return time.Time{} }))
+
+	var buf2 bytes.Buffer
+	l.SetHandler(NewWriterHandler(&buf2))
+
+	l.Info("hoge")
+	if buf.Len() != 0 {
+		t.Errorf("buf=%q, want empty", buf.String())
+	}
+	if !bytes.Contains(buf2.Bytes(), []byte("hoge")) {
+		t.Errorf("buf2=%q, want to contain %q", buf2.String(), "hoge")
+	}
+
+	if err := l.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown err=%v", err)
+	}
+}
+
+type blockingHandler struct{ block chan struct{} }
+
+func (h blockingHandler) Handle(Entry) error { <-h.block; return nil }
+func (h blockingHandler) Flush() error       { return nil }
+func (h blockingHandler) Close() error       { return nil }
+
+type erroringHandler struct{ err error }
+
+func (h erroringHandler) Handle(Entry) error { return h.err }
+func (h erroringHandler) Flush() error       { return h.err }
+func (h erroringHandler) Close() error       { return h.err }
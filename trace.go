@@ -0,0 +1,113 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// traceContext carries a trace/span ID and sampling decision extracted from
+// an incoming request header, for use when no OpenTelemetry span is present
+// in the context.
+type traceContext struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+var traceParentRe = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// ParseTraceParent parses the W3C traceparent header format
+// ("version-traceId-parentId-flags") and reports the trace ID, span ID and
+// sampling decision it carries. ok is false if header is not a valid
+// traceparent value.
+func ParseTraceParent(header string) (traceID, spanID string, sampled bool, ok bool) {
+	m := traceParentRe.FindStringSubmatch(header)
+	if m == nil {
+		return "", "", false, false
+	}
+	flags, err := strconv.ParseUint(m[3], 16, 8)
+	if err != nil {
+		return "", "", false, false
+	}
+	return m[1], m[2], flags&0x1 != 0, true
+}
+
+// ParseCloudTraceContext parses the X-Cloud-Trace-Context header format
+// ("TRACE_ID/SPAN_ID;o=OPTIONS") and reports the trace ID, span ID and
+// sampling decision it carries. The decimal SPAN_ID is returned hex-encoded
+// and zero-padded to 16 characters, matching the format Entry.SpanID
+// requires. ok is false if header is not a valid X-Cloud-Trace-Context
+// value.
+func ParseCloudTraceContext(header string) (traceID, spanID string, sampled bool, ok bool) {
+	traceID, rest, found := strings.Cut(header, "/")
+	if !found || traceID == "" {
+		return "", "", false, false
+	}
+
+	spanPart := rest
+	if idx := strings.IndexByte(rest, ';'); idx >= 0 {
+		spanPart = rest[:idx]
+		if _, opts, found := strings.Cut(rest[idx+1:], "o="); found {
+			if v, err := strconv.ParseUint(opts, 10, 8); err == nil {
+				sampled = v&0x1 != 0
+			}
+		}
+	}
+	if spanPart == "" {
+		return "", "", false, false
+	}
+	span, err := strconv.ParseUint(spanPart, 10, 64)
+	if err != nil {
+		return "", "", false, false
+	}
+
+	return traceID, fmt.Sprintf("%016x", span), sampled, true
+}
+
+// TraceContextFromRequest extracts trace context from r's headers, checking
+// the W3C traceparent header first and falling back to the GCP-specific
+// X-Cloud-Trace-Context header. ok is false if neither header is present or
+// valid.
+func TraceContextFromRequest(r *http.Request) (traceID, spanID string, sampled, ok bool) {
+	if h := r.Header.Get("traceparent"); h != "" {
+		if traceID, spanID, sampled, ok = ParseTraceParent(h); ok {
+			return traceID, spanID, sampled, true
+		}
+	}
+	if h := r.Header.Get("X-Cloud-Trace-Context"); h != "" {
+		if traceID, spanID, sampled, ok = ParseCloudTraceContext(h); ok {
+			return traceID, spanID, sampled, true
+		}
+	}
+	return "", "", false, false
+}
+
+// WithTraceContext returns a copy of ctx carrying traceID, spanID and
+// sampled. Subsequent *WithCtx log calls made with the returned context emit
+// the entry's trace, spanId and trace_sampled fields from it, unless an
+// OpenTelemetry span already live in the context takes precedence.
+func WithTraceContext(ctx context.Context, traceID, spanID string, sampled bool) context.Context {
+	return context.WithValue(ctx, ctxKeyTraceContext, &traceContext{traceID: traceID, spanID: spanID, sampled: sampled})
+}
+
+func traceContextFromContext(ctx context.Context) (*traceContext, bool) {
+	tc, ok := ctx.Value(ctxKeyTraceContext).(*traceContext)
+	return tc, ok
+}
+
+// TraceMiddleware attaches the trace context carried by an incoming request's
+// traceparent or X-Cloud-Trace-Context header to the request context, so that
+// *WithCtx log calls made while handling r emit the correct trace, spanId and
+// trace_sampled fields even when no OpenTelemetry tracer is installed.
+func TraceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if traceID, spanID, sampled, ok := TraceContextFromRequest(r); ok {
+			r = r.WithContext(WithTraceContext(r.Context(), traceID, spanID, sampled))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
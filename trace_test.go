@@ -0,0 +1,71 @@
+package slog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	for _, c := range []struct {
+		header  string
+		traceID string
+		spanID  string
+		sampled bool
+		ok      bool
+	}{
+		{
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, true,
+		},
+		{
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false, true,
+		},
+		{"not-a-traceparent", "", "", false, false},
+	} {
+		traceID, spanID, sampled, ok := ParseTraceParent(c.header)
+		if traceID != c.traceID || spanID != c.spanID || sampled != c.sampled || ok != c.ok {
+			t.Errorf("header=%q: got=(%q,%q,%v,%v), want=(%q,%q,%v,%v)", c.header, traceID, spanID, sampled, ok, c.traceID, c.spanID, c.sampled, c.ok)
+		}
+	}
+}
+
+func TestParseCloudTraceContext(t *testing.T) {
+	for _, c := range []struct {
+		header  string
+		traceID string
+		spanID  string
+		sampled bool
+		ok      bool
+	}{
+		{"105445aa7843bc8bf206b12000100000/1;o=1", "105445aa7843bc8bf206b12000100000", "0000000000000001", true, true},
+		{"105445aa7843bc8bf206b12000100000/1;o=0", "105445aa7843bc8bf206b12000100000", "0000000000000001", false, true},
+		{"105445aa7843bc8bf206b12000100000/1", "105445aa7843bc8bf206b12000100000", "0000000000000001", false, true},
+		{"105445aa7843bc8bf206b12000100000/not-a-number", "", "", false, false},
+		{"not-a-trace-context", "", "", false, false},
+	} {
+		traceID, spanID, sampled, ok := ParseCloudTraceContext(c.header)
+		if traceID != c.traceID || spanID != c.spanID || sampled != c.sampled || ok != c.ok {
+			t.Errorf("header=%q: got=(%q,%q,%v,%v), want=(%q,%q,%v,%v)", c.header, traceID, spanID, sampled, ok, c.traceID, c.spanID, c.sampled, c.ok)
+		}
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	var gotTC *traceContext
+	h := TraceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTC, _ = traceContextFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/path", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotTC == nil {
+		t.Fatal("traceContextFromContext returned nil")
+	}
+	if gotTC.traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || gotTC.spanID != "00f067aa0ba902b7" || !gotTC.sampled {
+		t.Errorf("got=%+v", gotTC)
+	}
+}